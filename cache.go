@@ -2,17 +2,77 @@ package tlru
 
 import (
 	"container/list"
+	"math"
 	"runtime"
 	"sync"
 	"time"
 )
 
+// NoExpiration is used with SetWithTTL to mark an item as never expiring.
+const NoExpiration time.Duration = -1
+
+// noExpirationAt is the expiresAt sentinel stored for items set with NoExpiration.
+const noExpirationAt = math.MaxInt64
+
+// EvictReason describes why an item was removed from the cache, passed to an OnEvicted callback.
+type EvictReason int
+
+const (
+	// EvictExpired means the item was removed because its TTL ran out.
+	EvictExpired EvictReason = iota
+
+	// EvictLRU means the item was removed to make room under MaxLength.
+	EvictLRU
+
+	// EvictBytes means the item was removed to make room under MaxBytes.
+	EvictBytes
+
+	// EvictManual means the item was removed by an explicit call to Delete or Erase.
+	EvictManual
+
+	// EvictReplaced means the item was removed because Set overwrote it with a new value.
+	EvictReplaced
+)
+
+// Option is used to configure a Cache at construction time. See WithOnEvicted and WithJanitorInterval.
+type Option func(*Cache)
+
+// WithOnEvicted registers a callback that is invoked whenever an item leaves the cache, along
+// with the EvictReason it left for. The callback is always invoked after c.m has been unlocked,
+// so it is safe for it to call back into the cache without deadlocking.
+func WithOnEvicted(OnEvicted func(key, value interface{}, reason EvictReason)) Option {
+	return func(c *Cache) {
+		c.onEvicted = OnEvicted
+	}
+}
+
+// WithJanitorInterval overrides how often the background janitor sweeps for expired items.
+// If not given, it defaults to Duration/10 (or a second, if that would be non-positive).
+func WithJanitorInterval(Interval time.Duration) Option {
+	return func(c *Cache) {
+		c.janitorInterval = Interval
+	}
+}
+
+// evicted records a single eviction so it can be dispatched to OnEvicted after c.m is unlocked.
+type evicted struct {
+	key    interface{}
+	value  interface{}
+	reason EvictReason
+}
+
 // Defines an item in the cache.
 type cacheItem struct {
-	item      interface{}
-	size      int
-	destroyer *time.Timer
-	element   *list.Element
+	item interface{}
+	size int
+
+	// ttl is the duration applied to this item each time it's revived.
+	ttl time.Duration
+
+	// expiresAt is the unix-nano instant this item should be considered expired.
+	expiresAt int64
+
+	element *list.Element
 }
 
 // Defines the cache and all required items.
@@ -29,11 +89,42 @@ type Cache struct {
 
 	// Define the duration of an item in the cache.
 	duration time.Duration
+
+	// Called, if set, whenever an item leaves the cache.
+	onEvicted func(key, value interface{}, reason EvictReason)
+
+	// Controls the background janitor that sweeps expired items.
+	janitorInterval time.Duration
+	closeOnce       sync.Once
+	stopJanitor     chan struct{}
+
+	// Guards in-flight GetOrLoad calls, keyed the same way as valueMap.
+	loadMu  sync.Mutex
+	loading map[interface{}]*inflight
+}
+
+// dispatchEvicted invokes c.onEvicted for each recorded eviction. Callers must invoke this after
+// c.m has been unlocked so that user callbacks can't deadlock the cache.
+func (c *Cache) dispatchEvicted(evictions []evicted) {
+	if c.onEvicted == nil {
+		return
+	}
+	for _, e := range evictions {
+		c.onEvicted(e.key, e.value, e.reason)
+	}
+}
+
+// expiresAtFor works out the expiresAt instant for a TTL, taking NoExpiration into account.
+func expiresAtFor(now int64, ttl time.Duration) int64 {
+	if ttl == NoExpiration {
+		return noExpirationAt
+	}
+	return now + ttl.Nanoseconds()
 }
 
 // Purge the first added item possible from the cache.
 // THIS FUNCTION IS NOT THREAD SAFE FOR PERFORMANCE REASONS (DOUBLE LOCKING)! BE CAREFUL!
-func (c *Cache) purgeFirst() {
+func (c *Cache) purgeFirst(reason EvictReason, evictions *[]evicted) {
 	f := c.keyList.Front()
 	if f == nil {
 		return
@@ -41,8 +132,52 @@ func (c *Cache) purgeFirst() {
 	c.keyList.Remove(f)
 	item := c.valueMap[f.Value]
 	c.totalBytes -= item.size
-	item.destroyer.Stop()
 	delete(c.valueMap, f.Value)
+	*evictions = append(*evictions, evicted{key: f.Value, value: item.item, reason: reason})
+}
+
+// janitor periodically sweeps expired items from the cache.
+func (c *Cache) janitor() {
+	ticker := time.NewTicker(c.janitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stopJanitor:
+			return
+		case <-ticker.C:
+			c.sweep()
+		}
+	}
+}
+
+// sweep evicts every expired item under a single lock acquisition. Per-item TTLs (including
+// NoExpiration, via SetWithTTL) mean the LRU list's touch order no longer tracks expiration
+// order, so every item has to be checked rather than stopping at the first unexpired one.
+func (c *Cache) sweep() {
+	now := time.Now().UnixNano()
+
+	c.m.Lock()
+	var evictions []evicted
+	for key, item := range c.valueMap {
+		if item.expiresAt > now {
+			continue
+		}
+		c.keyList.Remove(item.element)
+		c.totalBytes -= item.size
+		delete(c.valueMap, key)
+		evictions = append(evictions, evicted{key: key, value: item.item, reason: EvictExpired})
+	}
+	c.m.Unlock()
+
+	c.dispatchEvicted(evictions)
+}
+
+// Close stops the background janitor goroutine. It should be called once a Cache is no longer
+// needed to avoid leaking the goroutine.
+func (c *Cache) Close() {
+	c.closeOnce.Do(func() {
+		close(c.stopJanitor)
+	})
 }
 
 // Get is used to try and get a interface from the cache.
@@ -60,10 +195,21 @@ func (c *Cache) Get(Key interface{}) (item interface{}, ok bool) {
 		return nil, false
 	}
 
+	// Lazily expire the item if its TTL has already run out.
+	now := time.Now().UnixNano()
+	if x.expiresAt <= now {
+		c.keyList.Remove(x.element)
+		delete(c.valueMap, Key)
+		c.totalBytes -= x.size
+		c.m.Unlock()
+		c.dispatchEvicted([]evicted{{key: Key, value: x.item, reason: EvictExpired}})
+		return nil, false
+	}
+
 	// Revive the key.
 	c.keyList.Remove(x.element)
 	x.element = c.keyList.PushBack(Key)
-	x.destroyer.Reset(c.duration)
+	x.expiresAt = expiresAtFor(now, x.ttl)
 
 	// Unlock the mutex.
 	c.m.Unlock()
@@ -72,46 +218,49 @@ func (c *Cache) Get(Key interface{}) (item interface{}, ok bool) {
 	return x.item, true
 }
 
-// Used to generate a destruction function for a item.
-func (c *Cache) destroyItem(Key interface{}, timer bool) func() {
-	return func() {
-		// Lock the mutex.
-		c.m.Lock()
-
-		// Delete the item from the cache.
-		item := c.valueMap[Key]
-		c.keyList.Remove(item.element)
-		delete(c.valueMap, Key)
-		if !timer {
-			item.destroyer.Stop()
-		}
-		c.totalBytes -= item.size
+// Delete is used to delete an option from the cache.
+func (c *Cache) Delete(Key interface{}) {
+	c.m.Lock()
 
-		// Unlock the mutex.
+	item, ok := c.valueMap[Key]
+	if !ok {
 		c.m.Unlock()
+		return
 	}
-}
+	c.keyList.Remove(item.element)
+	delete(c.valueMap, Key)
+	c.totalBytes -= item.size
 
-// Delete is used to delete an option from the cache.
-func (c *Cache) Delete(Key interface{}) {
-	c.destroyItem(Key, false)()
+	c.m.Unlock()
+
+	c.dispatchEvicted([]evicted{{key: Key, value: item.item, reason: EvictManual}})
 }
 
 // Erase is used to erase the cache.
 func (c *Cache) Erase() {
 	c.m.Lock()
 	c.keyList = list.New()
-	for _, v := range c.valueMap {
-		v.destroyer.Stop()
+	var evictions []evicted
+	for k, v := range c.valueMap {
+		evictions = append(evictions, evicted{key: k, value: v.item, reason: EvictManual})
 	}
 	c.valueMap = map[interface{}]*cacheItem{}
 	c.m.Unlock()
 	c.totalBytes = 0
 	runtime.GC()
+
+	// Dispatch the eviction callbacks now that the mutex is released.
+	c.dispatchEvicted(evictions)
 }
 
-// Set is used to set a key/value interface in the cache.
+// Set is used to set a key/value interface in the cache, using the cache-wide duration.
 func (c *Cache) Set(Key, Value interface{}) {
+	c.SetWithTTL(Key, Value, c.duration)
+}
+
+// SetWithTTL is used to set a key/value interface in the cache with a TTL which overrides the
+// cache-wide duration for this entry. Pass NoExpiration to make the entry never expire.
+func (c *Cache) SetWithTTL(Key, Value interface{}, TTL time.Duration) {
 	// Lock the mutex.
 	c.m.Lock()
 
@@ -129,24 +278,31 @@ func (c *Cache) Set(Key, Value interface{}) {
 		}
 	}
 
+	// Collect evictions here and dispatch them once c.m is unlocked.
+	var evictions []evicted
+	now := time.Now().UnixNano()
+
 	// If the key already exists, we should revive the key. If not, we should push it and set a timer in the map.
 	if exists {
+		evictions = append(evictions, evicted{key: Key, value: item.item, reason: EvictReplaced})
 		c.keyList.Remove(item.element)
 		item.element = c.keyList.PushBack(Key)
-		item.destroyer.Reset(c.duration)
+		item.ttl = TTL
+		item.expiresAt = expiresAtFor(now, TTL)
 		item.item = Value
 	} else {
 		// If the length is the max length, remove one.
 		l := len(c.valueMap)
 		if l == c.maxLen && c.maxLen != 0 {
-			c.purgeFirst()
+			c.purgeFirst(EvictLRU, &evictions)
 		}
 
 		// Set the cache item.
 		el := c.keyList.PushBack(Key)
 		item = &cacheItem{
 			item:      Value,
-			destroyer: time.AfterFunc(c.duration, c.destroyItem(Key, true)),
+			ttl:       TTL,
+			expiresAt: expiresAtFor(now, TTL),
 			size:      total,
 			element:   el,
 		}
@@ -157,21 +313,41 @@ func (c *Cache) Set(Key, Value interface{}) {
 	// Ensure max bytes is greater than or equal to total bytes.
 	for c.totalBytes > c.maxBytes {
 		// Purge the first item.
-		c.purgeFirst()
+		c.purgeFirst(EvictBytes, &evictions)
 	}
 
 	// Unlock the mutex.
 	c.m.Unlock()
+
+	// Dispatch the eviction callbacks now that the mutex is released.
+	c.dispatchEvicted(evictions)
 }
 
 // NewCache is used to create the cache.
-// Setting MaxLength of MaxBytes to 0 will mean unlimited.
-func NewCache(MaxLength, MaxBytes int, Duration time.Duration) *Cache {
-	return &Cache{
-		keyList:  list.New(),
-		valueMap: map[interface{}]*cacheItem{},
-		maxLen:   MaxLength,
-		maxBytes: MaxBytes,
-		duration: Duration,
+// Setting MaxLength of MaxBytes to 0 will mean unlimited. Opts can be used to configure
+// optional behaviour, such as WithOnEvicted or WithJanitorInterval.
+//
+// The returned Cache runs a background janitor goroutine to sweep expired items; call Close
+// once the cache is no longer needed to stop it.
+func NewCache(MaxLength, MaxBytes int, Duration time.Duration, Opts ...Option) *Cache {
+	c := &Cache{
+		keyList:     list.New(),
+		valueMap:    map[interface{}]*cacheItem{},
+		maxLen:      MaxLength,
+		maxBytes:    MaxBytes,
+		duration:    Duration,
+		stopJanitor: make(chan struct{}),
+		loading:     map[interface{}]*inflight{},
+	}
+	for _, opt := range Opts {
+		opt(c)
+	}
+	if c.janitorInterval <= 0 {
+		c.janitorInterval = Duration / 10
+		if c.janitorInterval <= 0 {
+			c.janitorInterval = time.Second
+		}
 	}
+	go c.janitor()
+	return c
 }
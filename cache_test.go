@@ -1,7 +1,9 @@
 package tlru
 
 import (
+	"errors"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -60,6 +62,129 @@ func TestCacheExpiry(t *testing.T) {
 	}
 }
 
+func TestCacheSetWithTTLNoExpiration(t *testing.T) {
+	c := NewCache(10, 0, time.Millisecond)
+	c.SetWithTTL("a", true, NoExpiration)
+	time.Sleep(time.Millisecond * 3)
+	_, ok := c.Get("a")
+	if !ok {
+		t.Fatal("should be ok")
+	}
+}
+
+func TestCacheSetWithTTLOverride(t *testing.T) {
+	c := NewCache(10, 0, time.Second)
+	c.SetWithTTL("a", true, time.Millisecond)
+	time.Sleep(time.Millisecond * 3)
+	_, ok := c.Get("a")
+	if ok {
+		t.Fatal("should be not ok")
+	}
+}
+
+func TestCacheOnEvicted(t *testing.T) {
+	var mu sync.Mutex
+	var reasons []EvictReason
+	c := NewCache(1, 0, time.Second, WithOnEvicted(func(key, value interface{}, reason EvictReason) {
+		mu.Lock()
+		reasons = append(reasons, reason)
+		mu.Unlock()
+	}))
+	c.Set("a", true)
+	c.Set("b", true)
+	c.Delete("b")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reasons) != 2 {
+		t.Fatalf("expected 2 evictions, got %d", len(reasons))
+	}
+	if reasons[0] != EvictLRU {
+		t.Fatalf("expected EvictLRU, got %v", reasons[0])
+	}
+	if reasons[1] != EvictManual {
+		t.Fatalf("expected EvictManual, got %v", reasons[1])
+	}
+}
+
+func TestCacheSweepMixedTTL(t *testing.T) {
+	c := NewCache(10, 0, time.Second, WithJanitorInterval(time.Millisecond))
+	c.SetWithTTL("pinned", true, NoExpiration)
+	c.SetWithTTL("short", true, time.Millisecond)
+
+	time.Sleep(time.Millisecond * 50)
+
+	c.m.Lock()
+	_, stillThere := c.valueMap["short"]
+	c.m.Unlock()
+	if stillThere {
+		t.Fatal("short-lived item behind a pinned item should have been swept")
+	}
+
+	if _, ok := c.Get("pinned"); !ok {
+		t.Fatal("pinned item should still be cached")
+	}
+}
+
+func TestCacheClose(t *testing.T) {
+	c := NewCache(10, 0, time.Millisecond)
+	c.Set("a", true)
+	c.Close()
+}
+
+func TestCacheGetOrLoad(t *testing.T) {
+	c := NewCache(10, 0, time.Second)
+	var calls int32
+	loader := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(time.Millisecond * 10)
+		return "loaded", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 20)
+	wg.Add(20)
+	for i := 0; i < 20; i++ {
+		go func(index int) {
+			defer wg.Done()
+			v, err := c.GetOrLoad("a", loader)
+			if err != nil {
+				t.Error(err)
+			}
+			results[index] = v
+		}(i)
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected loader to be called once, got %d", calls)
+	}
+	for _, r := range results {
+		if r != "loaded" {
+			t.Fatalf("expected every caller to get \"loaded\", got %v", r)
+		}
+	}
+
+	r, ok := c.Get("a")
+	if !ok || r != "loaded" {
+		t.Fatal("expected the loaded value to be cached")
+	}
+}
+
+func TestCacheGetOrLoadError(t *testing.T) {
+	c := NewCache(10, 0, time.Second)
+	wantErr := errors.New("boom")
+	_, err := c.GetOrLoad("a", func() (interface{}, error) {
+		return nil, wantErr
+	})
+	if err != wantErr {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("a failed load should not be cached")
+	}
+}
+
 func TestCacheFilling(t *testing.T) {
 	c := NewCache(1, 0, time.Second)
 	c.Set("a", true)
@@ -0,0 +1,59 @@
+package tlru
+
+import (
+	"sync"
+	"time"
+)
+
+// inflight tracks a GetOrLoad call in progress for a single key, so concurrent callers for the
+// same key can wait on the one Loader call instead of each starting their own.
+type inflight struct {
+	wg    sync.WaitGroup
+	value interface{}
+	err   error
+}
+
+// GetOrLoad returns the cached value for Key if present. Otherwise, it calls Loader exactly
+// once - even if GetOrLoad is called concurrently for the same key - caches the result if
+// Loader didn't error, and returns it to every waiter. This turns the cache into a read-through
+// cache and avoids a thundering herd of Loader calls against an expensive upstream when a hot
+// key expires.
+func (c *Cache) GetOrLoad(Key interface{}, Loader func() (interface{}, error)) (interface{}, error) {
+	if value, ok := c.Get(Key); ok {
+		return value, nil
+	}
+
+	c.loadMu.Lock()
+	if f, ok := c.loading[Key]; ok {
+		c.loadMu.Unlock()
+		f.wg.Wait()
+		return f.value, f.err
+	}
+	// A load for this key may have finished between the Get above and taking loadMu. Peek at the
+	// map directly rather than calling Get, so no OnEvicted callback runs while loadMu is held.
+	c.m.Lock()
+	if x, ok := c.valueMap[Key]; ok && x.expiresAt > time.Now().UnixNano() {
+		value := x.item
+		c.m.Unlock()
+		c.loadMu.Unlock()
+		return value, nil
+	}
+	c.m.Unlock()
+	f := &inflight{}
+	f.wg.Add(1)
+	c.loading[Key] = f
+	c.loadMu.Unlock()
+
+	value, err := Loader()
+	if err == nil {
+		c.Set(Key, value)
+	}
+	f.value, f.err = value, err
+
+	c.loadMu.Lock()
+	delete(c.loading, Key)
+	c.loadMu.Unlock()
+
+	f.wg.Done()
+	return value, err
+}
@@ -0,0 +1,143 @@
+package tlru
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// HashFunc is used to pick which shard a key belongs to.
+type HashFunc func(key interface{}) uint64
+
+// defaultHashFunc hashes a key by way of its fmt.Sprint representation. This is convenient for
+// arbitrary interface{} keys, but a custom HashFunc should be preferred on hot paths.
+func defaultHashFunc(key interface{}) uint64 {
+	h := fnv.New64a()
+	_, _ = fmt.Fprint(h, key)
+	return h.Sum64()
+}
+
+// ShardedCache fans keys out across a fixed number of independent Cache shards, each with its
+// own mutex, so that concurrent callers touching different keys don't contend on a single lock.
+type ShardedCache struct {
+	shards   []*Cache
+	mask     uint64
+	hashFunc HashFunc
+}
+
+// shardFor returns the shard a given key belongs to.
+func (sc *ShardedCache) shardFor(key interface{}) *Cache {
+	return sc.shards[sc.hashFunc(key)&sc.mask]
+}
+
+// Get is used to try and get a interface from the cache.
+// The second boolean is meant to represent ok. If it's false, it was not in the cache.
+func (sc *ShardedCache) Get(Key interface{}) (item interface{}, ok bool) {
+	return sc.shardFor(Key).Get(Key)
+}
+
+// Set is used to set a key/value interface in the cache, using the cache-wide duration.
+func (sc *ShardedCache) Set(Key, Value interface{}) {
+	sc.shardFor(Key).Set(Key, Value)
+}
+
+// SetWithTTL is used to set a key/value interface in the cache with a TTL which overrides the
+// cache-wide duration for this entry. Pass NoExpiration to make the entry never expire.
+func (sc *ShardedCache) SetWithTTL(Key, Value interface{}, TTL time.Duration) {
+	sc.shardFor(Key).SetWithTTL(Key, Value, TTL)
+}
+
+// Delete is used to delete an option from the cache.
+func (sc *ShardedCache) Delete(Key interface{}) {
+	sc.shardFor(Key).Delete(Key)
+}
+
+// GetOrLoad returns the cached value for Key if present, otherwise calls Loader exactly once -
+// even under concurrent callers for the same key - and caches and returns its result. See
+// Cache.GetOrLoad.
+func (sc *ShardedCache) GetOrLoad(Key interface{}, Loader func() (interface{}, error)) (interface{}, error) {
+	return sc.shardFor(Key).GetOrLoad(Key, Loader)
+}
+
+// Erase is used to erase every shard of the cache.
+func (sc *ShardedCache) Erase() {
+	for _, shard := range sc.shards {
+		shard.Erase()
+	}
+}
+
+// Len returns the total number of items across all shards.
+func (sc *ShardedCache) Len() int {
+	total := 0
+	for _, shard := range sc.shards {
+		shard.m.Lock()
+		total += len(shard.valueMap)
+		shard.m.Unlock()
+	}
+	return total
+}
+
+// Bytes returns the total byte accounting across all shards.
+func (sc *ShardedCache) Bytes() int {
+	total := 0
+	for _, shard := range sc.shards {
+		shard.m.Lock()
+		total += shard.totalBytes
+		shard.m.Unlock()
+	}
+	return total
+}
+
+// Close stops the background janitor goroutine on every shard.
+func (sc *ShardedCache) Close() {
+	for _, shard := range sc.shards {
+		shard.Close()
+	}
+}
+
+// NewShardedCache creates a ShardedCache of NumShards independent shards, which must be a power
+// of two. MaxLength and MaxBytes are split evenly across shards, with any remainder spread one
+// by one across the first shards so no shard's share goes negative; if NumShards is greater
+// than MaxLength (or MaxBytes), every shard still gets a minimum share of 1 rather than being
+// left uncapped, so the aggregate cap may overshoot slightly rather than undershoot. Hash picks
+// which shard a key lands in; pass nil to hash the key's fmt.Sprint representation. Opts
+// configure every underlying shard, the same way they would NewCache.
+func NewShardedCache(NumShards, MaxLength, MaxBytes int, Duration time.Duration, Hash HashFunc, Opts ...Option) *ShardedCache {
+	if NumShards <= 0 || NumShards&(NumShards-1) != 0 {
+		panic("tlru: NumShards must be a power of two")
+	}
+	if Hash == nil {
+		Hash = defaultHashFunc
+	}
+
+	sc := &ShardedCache{
+		shards:   make([]*Cache, NumShards),
+		mask:     uint64(NumShards - 1),
+		hashFunc: Hash,
+	}
+
+	for i := range sc.shards {
+		sc.shards[i] = NewCache(shardShare(MaxLength, NumShards, i), shardShare(MaxBytes, NumShards, i), Duration, Opts...)
+	}
+	return sc
+}
+
+// shardShare splits n evenly across shards, handing the remainder to the first shards one at a
+// time (rather than piling it all onto the last shard) so that every share stays non-negative.
+// 0 (unlimited) stays 0 for every shard; any other n is floored at 1 per shard so a shard never
+// gets handed the "unlimited" sentinel by accident - when NumShards > n this means shares sum to
+// more than n, which is an intentional, documented tolerance rather than leaving some shards
+// uncapped.
+func shardShare(n, shards, index int) int {
+	if n == 0 {
+		return 0
+	}
+	share := n / shards
+	if index < n%shards {
+		share++
+	}
+	if share == 0 {
+		return 1
+	}
+	return share
+}
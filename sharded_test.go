@@ -0,0 +1,89 @@
+package tlru
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShardedCacheGetSet(t *testing.T) {
+	sc := NewShardedCache(4, 10, 0, time.Second, nil)
+	sc.Set("a", true)
+	r, ok := sc.Get("a")
+	if !ok {
+		t.Fatal("should be ok")
+	}
+	if r != true {
+		t.Fatal("should be true")
+	}
+}
+
+func TestShardedCacheLen(t *testing.T) {
+	sc := NewShardedCache(4, 0, 0, time.Second, nil)
+	for i := 0; i < 100; i++ {
+		sc.Set(i, i)
+	}
+	if sc.Len() != 100 {
+		t.Fatalf("expected 100 items, got %d", sc.Len())
+	}
+}
+
+func TestShardShareNonNegative(t *testing.T) {
+	// 100 doesn't divide evenly across 16 shards, and isn't a multiple of 15 (NumShards-1)
+	// either - this used to send the last shard a negative maxLen.
+	const n, shards = 100, 16
+	total := 0
+	for i := 0; i < shards; i++ {
+		share := shardShare(n, shards, i)
+		if share < 0 {
+			t.Fatalf("shard %d got a negative share: %d", i, share)
+		}
+		total += share
+	}
+	if total != n {
+		t.Fatalf("shares should sum to %d, got %d", n, total)
+	}
+}
+
+func TestShardShareFloorsToOneWhenSmallerThanShardCount(t *testing.T) {
+	// n (1) is smaller than shards (8), so a naive n/shards would hand out 0 - which NewCache
+	// treats as "unlimited" - to every shard but the first.
+	const n, shards = 1, 8
+	for i := 0; i < shards; i++ {
+		if share := shardShare(n, shards, i); share == 0 {
+			t.Fatalf("shard %d got a 0 share, which NewCache would treat as unlimited", i)
+		}
+	}
+}
+
+func TestShardedCacheCapsEachShardWhenShardsOutnumberMaxLength(t *testing.T) {
+	sc := NewShardedCache(8, 1, 0, time.Second, nil)
+	for i := 0; i < 1000; i++ {
+		sc.Set(i, i)
+	}
+	// Every shard is floored to a share of 1, so at most NumShards items can survive.
+	if got := sc.Len(); got > 8 {
+		t.Fatalf("expected at most 8 resident items across 8 shards each capped at 1, got %d", got)
+	}
+}
+
+func TestShardedCacheEvictsWithUnevenSplit(t *testing.T) {
+	// Route every key to shard 0 so its per-shard cap (shardShare(100, 16, 0) == 7) is
+	// actually exercised.
+	sc := NewShardedCache(16, 100, 0, time.Second, func(interface{}) uint64 { return 0 })
+	for i := 0; i < 50; i++ {
+		sc.Set(i, i)
+	}
+	want := shardShare(100, 16, 0)
+	if got := sc.Len(); got != want {
+		t.Fatalf("expected the capped shard to hold %d items, got %d", want, got)
+	}
+}
+
+func TestShardedCacheNotPowerOfTwo(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a non power-of-two shard count")
+		}
+	}()
+	NewShardedCache(3, 0, 0, time.Second, nil)
+}
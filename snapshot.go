@@ -0,0 +1,105 @@
+package tlru
+
+import (
+	"encoding/gob"
+	"io"
+	"os"
+	"time"
+)
+
+// noRemainingTTL marks a snapshotEntry whose item was set with NoExpiration.
+const noRemainingTTL = -1
+
+// snapshotEntry is the gob-encoded form of a single cache item. RemainingNanos is the TTL that
+// was left at save time (or noRemainingTTL for an item that never expires), rather than the
+// original TTL, so that Load can carry on from where Save left off.
+type snapshotEntry struct {
+	Key            interface{}
+	Value          interface{}
+	RemainingNanos int64
+}
+
+// snapshot is the gob-encoded form of an entire Cache, in LRU order from oldest to newest.
+type snapshot struct {
+	MaxLen   int
+	MaxBytes int
+	Duration time.Duration
+	Entries  []snapshotEntry
+}
+
+// Save writes every key/value pair in the cache, along with each item's remaining TTL, to w
+// using encoding/gob. Callers must gob.Register any concrete value types they store in the
+// cache before calling Save or Load.
+func (c *Cache) Save(w io.Writer) error {
+	now := time.Now().UnixNano()
+
+	c.m.Lock()
+	s := snapshot{
+		MaxLen:   c.maxLen,
+		MaxBytes: c.maxBytes,
+		Duration: c.duration,
+		Entries:  make([]snapshotEntry, 0, len(c.valueMap)),
+	}
+	for e := c.keyList.Front(); e != nil; e = e.Next() {
+		item := c.valueMap[e.Value]
+		remaining := int64(noRemainingTTL)
+		if item.expiresAt != noExpirationAt {
+			remaining = item.expiresAt - now
+			if remaining < 0 {
+				// Already expired; don't bother saving it.
+				continue
+			}
+		}
+		s.Entries = append(s.Entries, snapshotEntry{
+			Key:            e.Value,
+			Value:          item.item,
+			RemainingNanos: remaining,
+		})
+	}
+	c.m.Unlock()
+
+	return gob.NewEncoder(w).Encode(&s)
+}
+
+// Load reads a snapshot written by Save from r and rehydrates it into a new Cache, translating
+// each item's remaining TTL at save time into fresh expiration so that it continues from where
+// it left off. Opts are applied to the new Cache the same way they would NewCache's, and can be
+// used to reattach things a gob snapshot can't carry, such as WithOnEvicted.
+// Callers must gob.Register any concrete value types they stored before calling Save.
+func Load(r io.Reader, Opts ...Option) (*Cache, error) {
+	var s snapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+
+	c := NewCache(s.MaxLen, s.MaxBytes, s.Duration, Opts...)
+	for _, e := range s.Entries {
+		ttl := time.Duration(e.RemainingNanos)
+		if e.RemainingNanos == noRemainingTTL {
+			ttl = NoExpiration
+		}
+		c.SetWithTTL(e.Key, e.Value, ttl)
+	}
+	return c, nil
+}
+
+// SaveFile is a convenience wrapper around Save that writes the snapshot to a file at path,
+// creating or truncating it as needed.
+func (c *Cache) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Save(f)
+}
+
+// LoadFile is a convenience wrapper around Load that reads the snapshot from a file at path.
+func LoadFile(path string, Opts ...Option) (*Cache, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f, Opts...)
+}
@@ -0,0 +1,51 @@
+package tlru
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestCacheSaveLoad(t *testing.T) {
+	c := NewCache(10, 0, time.Second)
+	c.Set("a", true)
+	c.SetWithTTL("b", false, NoExpiration)
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	r, ok := loaded.Get("a")
+	if !ok || r != true {
+		t.Fatal("expected \"a\" to be restored as true")
+	}
+	r, ok = loaded.Get("b")
+	if !ok || r != false {
+		t.Fatal("expected \"b\" to be restored as false")
+	}
+}
+
+func TestCacheSaveLoadFile(t *testing.T) {
+	c := NewCache(10, 0, time.Second)
+	c.Set("a", true)
+
+	path := t.TempDir() + "/snapshot.gob"
+	if err := c.SaveFile(path); err != nil {
+		t.Fatalf("save file: %v", err)
+	}
+
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("load file: %v", err)
+	}
+	r, ok := loaded.Get("a")
+	if !ok || r != true {
+		t.Fatal("expected \"a\" to be restored as true")
+	}
+}
@@ -0,0 +1,367 @@
+package tlru
+
+import (
+	"container/list"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// SizeFunc is used to work out the size (in bytes) of a value being stored in the cache.
+// This is used in place of the reflection based sizing that the non-generic v1 cache relies on.
+type SizeFunc[V any] func(Value V) int
+
+// NoExpiration is used with SetWithTTL to mark an item as never expiring.
+const NoExpiration time.Duration = -1
+
+// noExpirationAt is the expiresAt sentinel stored for items set with NoExpiration.
+const noExpirationAt = math.MaxInt64
+
+// EvictReason describes why an item was removed from the cache, passed to an OnEvicted callback.
+type EvictReason int
+
+const (
+	// EvictExpired means the item was removed because its TTL ran out.
+	EvictExpired EvictReason = iota
+
+	// EvictLRU means the item was removed to make room under MaxLength.
+	EvictLRU
+
+	// EvictBytes means the item was removed to make room under MaxBytes.
+	EvictBytes
+
+	// EvictManual means the item was removed by an explicit call to Delete or Erase.
+	EvictManual
+
+	// EvictReplaced means the item was removed because Set overwrote it with a new value.
+	EvictReplaced
+)
+
+// Option is used to configure a Cache at construction time. See WithOnEvicted and WithJanitorInterval.
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithOnEvicted registers a callback that is invoked whenever an item leaves the cache, along
+// with the EvictReason it left for. The callback is always invoked after c.m has been unlocked,
+// so it is safe for it to call back into the cache without deadlocking.
+func WithOnEvicted[K comparable, V any](OnEvicted func(key K, value V, reason EvictReason)) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.onEvicted = OnEvicted
+	}
+}
+
+// WithJanitorInterval overrides how often the background janitor sweeps for expired items.
+// If not given, it defaults to Duration/10 (or a second, if that would be non-positive).
+func WithJanitorInterval[K comparable, V any](Interval time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.janitorInterval = Interval
+	}
+}
+
+// evicted records a single eviction so it can be dispatched to OnEvicted after c.m is unlocked.
+type evicted[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictReason
+}
+
+// expiresAtFor works out the expiresAt instant for a TTL, taking NoExpiration into account.
+func expiresAtFor(now int64, ttl time.Duration) int64 {
+	if ttl == NoExpiration {
+		return noExpirationAt
+	}
+	return now + ttl.Nanoseconds()
+}
+
+// Defines an item in the cache.
+type cacheItem[K comparable, V any] struct {
+	item V
+	size int
+
+	// ttl is the duration applied to this item each time it's revived.
+	ttl time.Duration
+
+	// expiresAt is the unix-nano instant this item should be considered expired.
+	expiresAt int64
+
+	element *list.Element
+}
+
+// Cache defines a generic, typed version of the tlru cache.
+// Unlike the v1 Cache, keys and values are statically typed, which removes the interface{}
+// boxing and the type assertions callers previously had to do.
+type Cache[K comparable, V any] struct {
+	// Defines the attributes for the base cache.
+	m        sync.Mutex
+	keyList  *list.List
+	valueMap map[K]*cacheItem[K, V]
+	maxLen   int
+	maxBytes int
+
+	// The total size of items in the cache.
+	totalBytes int
+
+	// Define the duration of an item in the cache.
+	duration time.Duration
+
+	// Used to work out the size of a value. If nil, byte accounting is disabled.
+	sizeFunc SizeFunc[V]
+
+	// Called, if set, whenever an item leaves the cache.
+	onEvicted func(key K, value V, reason EvictReason)
+
+	// Controls the background janitor that sweeps expired items.
+	janitorInterval time.Duration
+	closeOnce       sync.Once
+	stopJanitor     chan struct{}
+}
+
+// dispatchEvicted invokes c.onEvicted for each recorded eviction. Callers must invoke this after
+// c.m has been unlocked so that user callbacks can't deadlock the cache.
+func (c *Cache[K, V]) dispatchEvicted(evictions []evicted[K, V]) {
+	if c.onEvicted == nil {
+		return
+	}
+	for _, e := range evictions {
+		c.onEvicted(e.key, e.value, e.reason)
+	}
+}
+
+// Purge the first added item possible from the cache.
+// THIS FUNCTION IS NOT THREAD SAFE FOR PERFORMANCE REASONS (DOUBLE LOCKING)! BE CAREFUL!
+func (c *Cache[K, V]) purgeFirst(reason EvictReason, evictions *[]evicted[K, V]) {
+	f := c.keyList.Front()
+	if f == nil {
+		return
+	}
+	c.keyList.Remove(f)
+	key := f.Value.(K)
+	item := c.valueMap[key]
+	c.totalBytes -= item.size
+	delete(c.valueMap, key)
+	*evictions = append(*evictions, evicted[K, V]{key: key, value: item.item, reason: reason})
+}
+
+// janitor periodically sweeps expired items from the cache.
+func (c *Cache[K, V]) janitor() {
+	ticker := time.NewTicker(c.janitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stopJanitor:
+			return
+		case <-ticker.C:
+			c.sweep()
+		}
+	}
+}
+
+// sweep evicts every expired item under a single lock acquisition. Per-item TTLs (including
+// NoExpiration, via SetWithTTL) mean the LRU list's touch order no longer tracks expiration
+// order, so every item has to be checked rather than stopping at the first unexpired one.
+func (c *Cache[K, V]) sweep() {
+	now := time.Now().UnixNano()
+
+	c.m.Lock()
+	var evictions []evicted[K, V]
+	for key, item := range c.valueMap {
+		if item.expiresAt > now {
+			continue
+		}
+		c.keyList.Remove(item.element)
+		c.totalBytes -= item.size
+		delete(c.valueMap, key)
+		evictions = append(evictions, evicted[K, V]{key: key, value: item.item, reason: EvictExpired})
+	}
+	c.m.Unlock()
+
+	c.dispatchEvicted(evictions)
+}
+
+// Close stops the background janitor goroutine. It should be called once a Cache is no longer
+// needed to avoid leaking the goroutine.
+func (c *Cache[K, V]) Close() {
+	c.closeOnce.Do(func() {
+		close(c.stopJanitor)
+	})
+}
+
+// Get is used to try and get a value from the cache.
+// The second boolean is meant to represent ok. If it's false, it was not in the cache.
+func (c *Cache[K, V]) Get(Key K) (item V, ok bool) {
+	// Lock the mutex.
+	c.m.Lock()
+
+	// Try to get from the cache.
+	x, ok := c.valueMap[Key]
+
+	// If this isn't ok, we return here.
+	if !ok {
+		c.m.Unlock()
+		var zero V
+		return zero, false
+	}
+
+	// Lazily expire the item if its TTL has already run out.
+	now := time.Now().UnixNano()
+	if x.expiresAt <= now {
+		c.keyList.Remove(x.element)
+		delete(c.valueMap, Key)
+		c.totalBytes -= x.size
+		c.m.Unlock()
+		c.dispatchEvicted([]evicted[K, V]{{key: Key, value: x.item, reason: EvictExpired}})
+		var zero V
+		return zero, false
+	}
+
+	// Revive the key.
+	c.keyList.Remove(x.element)
+	x.element = c.keyList.PushBack(Key)
+	x.expiresAt = expiresAtFor(now, x.ttl)
+
+	// Unlock the mutex.
+	c.m.Unlock()
+
+	// Return the item.
+	return x.item, true
+}
+
+// Delete is used to delete an option from the cache.
+func (c *Cache[K, V]) Delete(Key K) {
+	c.m.Lock()
+
+	item, ok := c.valueMap[Key]
+	if !ok {
+		c.m.Unlock()
+		return
+	}
+	c.keyList.Remove(item.element)
+	delete(c.valueMap, Key)
+	c.totalBytes -= item.size
+
+	c.m.Unlock()
+
+	c.dispatchEvicted([]evicted[K, V]{{key: Key, value: item.item, reason: EvictManual}})
+}
+
+// Erase is used to erase the cache.
+func (c *Cache[K, V]) Erase() {
+	c.m.Lock()
+	c.keyList = list.New()
+	var evictions []evicted[K, V]
+	for k, v := range c.valueMap {
+		evictions = append(evictions, evicted[K, V]{key: k, value: v.item, reason: EvictManual})
+	}
+	c.valueMap = map[K]*cacheItem[K, V]{}
+	c.m.Unlock()
+	c.totalBytes = 0
+	runtime.GC()
+
+	// Dispatch the eviction callbacks now that the mutex is released.
+	c.dispatchEvicted(evictions)
+}
+
+// Set is used to set a key/value pair in the cache, using the cache-wide duration.
+func (c *Cache[K, V]) Set(Key K, Value V) {
+	c.SetWithTTL(Key, Value, c.duration)
+}
+
+// SetWithTTL is used to set a key/value pair in the cache with a TTL which overrides the
+// cache-wide duration for this entry. Pass NoExpiration to make the entry never expire.
+func (c *Cache[K, V]) SetWithTTL(Key K, Value V, TTL time.Duration) {
+	// Lock the mutex.
+	c.m.Lock()
+
+	// Check if the key already exists and the length.
+	item, exists := c.valueMap[Key]
+
+	// Get the total size.
+	var total int
+	if c.maxBytes != 0 && c.sizeFunc != nil {
+		total = c.sizeFunc(Value)
+		if total > c.maxBytes {
+			// Don't cache this.
+			c.m.Unlock()
+			return
+		}
+	}
+
+	// Collect evictions here and dispatch them once c.m is unlocked.
+	var evictions []evicted[K, V]
+	now := time.Now().UnixNano()
+
+	// If the key already exists, we should revive the key. If not, we should push it and store its expiry in the map.
+	if exists {
+		evictions = append(evictions, evicted[K, V]{key: Key, value: item.item, reason: EvictReplaced})
+		c.keyList.Remove(item.element)
+		item.element = c.keyList.PushBack(Key)
+		item.ttl = TTL
+		item.expiresAt = expiresAtFor(now, TTL)
+		item.item = Value
+	} else {
+		// If the length is the max length, remove one.
+		l := len(c.valueMap)
+		if l == c.maxLen && c.maxLen != 0 {
+			c.purgeFirst(EvictLRU, &evictions)
+		}
+
+		// Set the cache item.
+		el := c.keyList.PushBack(Key)
+		item = &cacheItem[K, V]{
+			item:      Value,
+			ttl:       TTL,
+			expiresAt: expiresAtFor(now, TTL),
+			size:      total,
+			element:   el,
+		}
+		c.valueMap[Key] = item
+		c.totalBytes += total
+	}
+
+	// Ensure max bytes is greater than or equal to total bytes.
+	for c.totalBytes > c.maxBytes {
+		// Purge the first item.
+		c.purgeFirst(EvictBytes, &evictions)
+	}
+
+	// Unlock the mutex.
+	c.m.Unlock()
+
+	// Dispatch the eviction callbacks now that the mutex is released.
+	c.dispatchEvicted(evictions)
+}
+
+// NewCache is used to create the cache.
+// Setting MaxLength or MaxBytes to 0 will mean unlimited. Size may be nil only if MaxBytes is 0
+// - a non-zero MaxBytes with a nil Size panics, rather than silently never enforcing the byte
+// cap. Opts can be used to configure optional behaviour, such as WithOnEvicted or
+// WithJanitorInterval.
+//
+// The returned Cache runs a background janitor goroutine to sweep expired items; call Close
+// once the cache is no longer needed to stop it.
+func NewCache[K comparable, V any](MaxLength, MaxBytes int, Duration time.Duration, Size SizeFunc[V], Opts ...Option[K, V]) *Cache[K, V] {
+	if MaxBytes != 0 && Size == nil {
+		panic("tlru: MaxBytes is non-zero but Size is nil, so the byte cap could never be enforced")
+	}
+
+	c := &Cache[K, V]{
+		keyList:     list.New(),
+		valueMap:    map[K]*cacheItem[K, V]{},
+		maxLen:      MaxLength,
+		maxBytes:    MaxBytes,
+		duration:    Duration,
+		sizeFunc:    Size,
+		stopJanitor: make(chan struct{}),
+	}
+	for _, opt := range Opts {
+		opt(c)
+	}
+	if c.janitorInterval <= 0 {
+		c.janitorInterval = Duration / 10
+		if c.janitorInterval <= 0 {
+			c.janitorInterval = time.Second
+		}
+	}
+	go c.janitor()
+	return c
+}
@@ -0,0 +1,159 @@
+package tlru
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacheGetSet(t *testing.T) {
+	c := NewCache[string, bool](1, 0, time.Second, nil)
+	c.Set("a", true)
+	r, ok := c.Get("a")
+	if !ok {
+		t.Fatal("should be ok")
+		return
+	}
+	if r != true {
+		t.Fatal("should be true")
+	}
+}
+
+func TestCacheMemoryEviction(t *testing.T) {
+	c := NewCache[string, bool](10, 1, time.Millisecond*3, func(bool) int { return 1 })
+	c.Set("a", true)
+	_, ok := c.Get("a")
+	if !ok {
+		t.Fatal("should be ok")
+	}
+	c.Set("b", true)
+	_, ok = c.Get("b")
+	if !ok {
+		t.Fatal("should be ok")
+	}
+	_, ok = c.Get("a")
+	if ok {
+		t.Fatal("should be not ok")
+	}
+	time.Sleep(time.Millisecond * 5)
+	if c.totalBytes != 0 {
+		t.Fatal("total bytes hasn't reset")
+	}
+}
+
+func TestCacheNoItemFound(t *testing.T) {
+	c := NewCache[string, bool](1, 0, time.Second, nil)
+	c.Set("a", true)
+	_, ok := c.Get("b")
+	if ok {
+		t.Fatal("should be not ok")
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	c := NewCache[string, bool](1, 0, time.Millisecond, nil)
+	c.Set("a", true)
+	time.Sleep(time.Millisecond * 3)
+	_, ok := c.Get("a")
+	if ok {
+		t.Fatal("should be not ok")
+	}
+}
+
+func TestCacheFilling(t *testing.T) {
+	c := NewCache[string, bool](1, 0, time.Second, nil)
+	c.Set("a", true)
+	c.Set("b", true)
+	_, ok := c.Get("a")
+	if ok {
+		t.Fatal("should be not ok")
+		return
+	}
+	r, ok := c.Get("b")
+	if !ok {
+		t.Fatal("should be ok")
+		return
+	}
+	if r != true {
+		t.Fatal("should be true")
+	}
+}
+
+func TestCacheSetWithTTLNoExpiration(t *testing.T) {
+	c := NewCache[string, bool](10, 0, time.Millisecond, nil)
+	c.SetWithTTL("a", true, NoExpiration)
+	time.Sleep(time.Millisecond * 3)
+	_, ok := c.Get("a")
+	if !ok {
+		t.Fatal("should be ok")
+	}
+}
+
+func TestCacheSetWithTTLOverride(t *testing.T) {
+	c := NewCache[string, bool](10, 0, time.Second, nil)
+	c.SetWithTTL("a", true, time.Millisecond)
+	time.Sleep(time.Millisecond * 3)
+	_, ok := c.Get("a")
+	if ok {
+		t.Fatal("should be not ok")
+	}
+}
+
+func TestCacheOnEvicted(t *testing.T) {
+	var mu sync.Mutex
+	var reasons []EvictReason
+	c := NewCache[string, bool](1, 0, time.Second, nil, WithOnEvicted[string, bool](func(key string, value bool, reason EvictReason) {
+		mu.Lock()
+		reasons = append(reasons, reason)
+		mu.Unlock()
+	}))
+	c.Set("a", true)
+	c.Set("b", true)
+	c.Delete("b")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reasons) != 2 {
+		t.Fatalf("expected 2 evictions, got %d", len(reasons))
+	}
+	if reasons[0] != EvictLRU {
+		t.Fatalf("expected EvictLRU, got %v", reasons[0])
+	}
+	if reasons[1] != EvictManual {
+		t.Fatalf("expected EvictManual, got %v", reasons[1])
+	}
+}
+
+func TestCacheSweepMixedTTL(t *testing.T) {
+	c := NewCache[string, bool](10, 0, time.Second, nil, WithJanitorInterval[string, bool](time.Millisecond))
+	c.SetWithTTL("pinned", true, NoExpiration)
+	c.SetWithTTL("short", true, time.Millisecond)
+
+	time.Sleep(time.Millisecond * 50)
+
+	c.m.Lock()
+	_, stillThere := c.valueMap["short"]
+	c.m.Unlock()
+	if stillThere {
+		t.Fatal("short-lived item behind a pinned item should have been swept")
+	}
+
+	if _, ok := c.Get("pinned"); !ok {
+		t.Fatal("pinned item should still be cached")
+	}
+}
+
+func TestNewCacheMaxBytesWithoutSizePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for MaxBytes != 0 with a nil Size")
+		}
+	}()
+	NewCache[string, bool](10, 1, time.Second, nil)
+}
+
+func TestCacheClose(t *testing.T) {
+	c := NewCache[string, bool](10, 0, time.Millisecond, nil)
+	c.Set("a", true)
+	c.Close()
+}